@@ -1,180 +1,50 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strings"
 	"time"
 
-	"github.com/gocolly/colly"
 	"github.com/urfave/cli/v2"
-)
-
-type CompanyRegistration struct {
-	RegNo      string     `json:"regno"`
-	Date       *time.Time `json:"date"`
-	Address    string     `json:"address"`
-	City       string     `json:"city"`
-	PostalCode string     `json:"postalCode"`
-	Name       string     `json:"name"`
-}
-
-type filterDate struct {
-	day   int
-	month string
-	year  int
-}
-
-func extractCompanyNumber(text string) (string, error) {
-	r := regexp.MustCompile(`.*:\s(.*)\n.*`)
-	matches := r.FindStringSubmatch(text)
-	if len(matches) > 0 {
-		return matches[1], nil
-	}
-
-	return "", fmt.Errorf("unable to extract company registration number: %s", text)
-}
-
-func extractCompanyRegistrationDate(text string) (*time.Time, error) {
-	r := regexp.MustCompile(`.*Bekannt gemacht am:(.*)Uhr`)
-	matches := r.FindStringSubmatch(text)
-	if len(matches) > 0 {
-		layout := "02.01.2006 15:04"
-		t, err := time.Parse(layout, strings.TrimSpace(matches[1]))
-		if err != nil {
-			return nil, fmt.Errorf("unable to extract company reg date: %s", err.Error())
-		}
-
-		return &t, nil
-	}
-
-	return nil, errors.New("unable to extract company registration date")
-}
 
-func extractCompanyName(text string) (string, error) {
-	sections := strings.Split(text, ",")
-	parts := strings.Split(sections[0], ": ")
+	"github.com/cameronnorman/company_registraions_cli/internal/crawler"
+	"github.com/cameronnorman/company_registraions_cli/internal/daterange"
+	"github.com/cameronnorman/company_registraions_cli/internal/sink"
+)
 
-	return parts[1], nil
+// germanStates holds the 16 official abbreviations accepted by the
+// Handelsregisterbekanntmachungen search form, in the order they are
+// listed on the site itself.
+var germanStates = []string{
+	"bw", "by", "be", "bb", "hb", "hh", "he", "mv",
+	"ni", "nw", "rp", "sl", "sn", "st", "sh", "th",
 }
 
-func extractCompanyAddress(text string) (string, error) {
-	sections := strings.Split(text, ",")
-
-	if len(sections[2]) > 35 {
-		fmt.Println(text)
+// resolveLands expands the `--land` flag values into the concrete set of
+// state abbreviations to crawl. An empty selection defaults to `bw` to
+// preserve the tool's previous behaviour; `all` expands to every state.
+func resolveLands(selected []string) ([]string, error) {
+	if len(selected) == 0 {
+		return []string{"bw"}, nil
 	}
 
-	return sections[2], nil
-}
-
-func extractCity(text string) (string, error) {
-	sections := strings.Split(text, ",")
-	return sections[1], nil
-}
-
-func extractPostalCode(text string) (string, error) {
-	r := regexp.MustCompile(`.*(\d{5}).*`)
-	matches := r.FindStringSubmatch(text)
-	if len(matches) > 0 {
-		return matches[1], nil
+	valid := make(map[string]bool, len(germanStates))
+	for _, land := range germanStates {
+		valid[land] = true
 	}
 
-	return "", fmt.Errorf("unable to extract company postal code: %v", text)
-}
-
-func collectRegistrations(startDate time.Time, endDate time.Time) []CompanyRegistration {
-	c := colly.NewCollector()
-	registrations := []CompanyRegistration{}
-
-	c.OnHTML("li>a[href]", func(e *colly.HTMLElement) {
-		href := e.Attr("href")
-		r := regexp.MustCompile(`.*'rb_id=(.*)\&.*`)
-		matches := r.FindStringSubmatch(href)
-		if len(matches) > 0 {
-			regID := matches[1]
-			regURL := fmt.Sprintf("https://www.handelsregisterbekanntmachungen.de/skripte/hrb.php?rb_id=%s&land_abk=bw", regID)
-			c.Visit(regURL)
+	for _, land := range selected {
+		if land == "all" {
+			return germanStates, nil
 		}
-	})
-
-	c.OnHTML("font", func(e *colly.HTMLElement) {
-		lines := []string{}
-		e.ForEach("tr", func(count int, ee *colly.HTMLElement) {
-			lines = append(lines, ee.Text)
-		})
-
-		if len(lines) > 0 {
-			reg := CompanyRegistration{}
-			courtFileNumber, err := extractCompanyNumber(lines[0])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.RegNo = strings.TrimSpace(courtFileNumber)
-
-			companyRegistrationDate, err := extractCompanyRegistrationDate(lines[0])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.Date = companyRegistrationDate
-
-			companyName, err := extractCompanyName(lines[5])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.Name = strings.TrimSpace(companyName)
-
-			companyAddress, err := extractCompanyAddress(lines[5])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.Address = strings.TrimSpace(companyAddress)
-
-			companyCity, err := extractCity(lines[5])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.City = strings.TrimSpace(companyCity)
-
-			postalCode, err := extractPostalCode(lines[5])
-			if err != nil {
-				log.Println(err.Error())
-			}
-			reg.PostalCode = strings.TrimSpace(postalCode)
-
-			registrations = append(registrations, reg)
+		if !valid[land] {
+			return nil, fmt.Errorf("unknown --land value: %s", land)
 		}
-	})
-
-	data := map[string]string{
-		"suchart":      "uneingeschr",
-		"button":       "Suche+starten",
-		"vt":           fmt.Sprintf("%d", startDate.Day()),
-		"vm":           fmt.Sprintf("%d", (startDate.Month())),
-		"vj":           fmt.Sprintf("%d", startDate.Year()),
-		"bt":           fmt.Sprintf("%d", endDate.Day()),
-		"bm":           fmt.Sprintf("%d", (endDate.Month())),
-		"bj":           fmt.Sprintf("%d", endDate.Year()),
-		"land":         "",
-		"gericht":      "",
-		"gericht_name": "",
-		"seite":        "",
-		"l":            "",
-		"r":            "",
-		"all":          "false",
-		"rubrik":       "",
-		"az":           "",
-		"gegenstand":   "0",
-		"order":        "4",
 	}
 
-	c.Post("https://www.handelsregisterbekanntmachungen.de/?aktion=suche#Ergebnis", data)
-
-	return registrations
+	return selected, nil
 }
 
 func main() {
@@ -182,53 +52,123 @@ func main() {
 		Name:  "Company Registrations fetcher",
 		Usage: "Fetches German company registrations",
 		Flags: []cli.Flag{
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:  "output",
-				Value: "csv",
-				Usage: "Specify which output format you want. (CSV and jsonl supported)",
+				Value: cli.NewStringSlice("stdout:csv"),
+				Usage: "Sink to write registrations to, repeatable: csv:path, jsonl:path, sqlite:file.db, webhook:https://..., stdout:csv or stdout:jsonl",
+			},
+			&cli.StringFlag{
+				Name:  "timestamp_suffix",
+				Value: "",
+				Usage: "Suffix appended to file sink names before their extension, e.g. the run time",
+			},
+			&cli.StringFlag{
+				Name:  "date_range",
+				Value: "today",
+				Usage: "Date range to search. Accepts YYYY-MM-DD, DD.MM.YYYY, today, yesterday, last-week, last-month, or a \"start..end\" pair (a blank end means today; the start may not be blank)",
+			},
+			&cli.DurationFlag{
+				Name:  "window",
+				Value: 24 * time.Hour,
+				Usage: "Size of each search query submitted within the date range, e.g. 24h or 168h for week-by-week",
+			},
+			&cli.StringSliceFlag{
+				Name:  "land",
+				Usage: "Federal state (Land) to search, repeatable (bw, by, be, bb, hb, hh, he, mv, ni, nw, rp, sl, sn, st, sh, th, or all). Defaults to bw.",
+			},
+			&cli.StringFlag{
+				Name:  "rubrik",
+				Value: "",
+				Usage: "Restrict the search to a register rubrik (e.g. HRA, HRB, GnR, PR, VR)",
+			},
+			&cli.IntFlag{
+				Name:  "parallelism",
+				Value: 2,
+				Usage: "Maximum number of concurrent requests to the Handelsregister domain",
+			},
+			&cli.DurationFlag{
+				Name:  "random_delay",
+				Value: 2 * time.Second,
+				Usage: "Random jitter added between requests to the Handelsregister domain",
+			},
+			&cli.StringFlag{
+				Name:  "cache_dir",
+				Value: "",
+				Usage: "Directory to cache fetched HTML pages in, so retries and re-runs don't re-fetch them",
 			},
 			&cli.StringFlag{
-				Name:  "start_date",
-				Value: fmt.Sprintf("%d-%d-%d", time.Now().Year(), int(time.Now().Month()), time.Now().Day()),
-				Usage: "Specify the start date you would like to use (YYYY-mm-dd)",
+				Name:  "checkpoint",
+				Value: "",
+				Usage: "File recording notices already processed, so --resume can skip them after a crash",
+			},
+			&cli.BoolFlag{
+				Name:  "resume",
+				Value: false,
+				Usage: "Skip notices already recorded in --checkpoint",
 			},
 			&cli.StringFlag{
-				Name:  "end_date",
-				Value: fmt.Sprintf("%d-%d-%d", time.Now().Year(), int(time.Now().Month()), time.Now().Day()),
-				Usage: "Specify the end date you would like to use (YYYY-mm-dd)",
+				Name:  "logger",
+				Value: "",
+				Usage: "File to write structured JSON logs of crawl errors and per-URL timings to",
 			},
 		},
 		Action: func(c *cli.Context) error {
-			layout := "2006-01-02"
-			startDate, err := time.Parse(layout, strings.TrimSpace(c.String("start_date")))
+			startDate, endDate, err := daterange.Parse(c.String("date_range"))
 			if err != nil {
-				return fmt.Errorf("unable to parse start date parameter: %s", err.Error())
+				return fmt.Errorf("unable to parse --date_range: %s", err.Error())
+			}
+			if startDate.IsZero() || endDate.IsZero() {
+				return fmt.Errorf("--date_range %q is open-ended on one side; supply both a start and an end", c.String("date_range"))
 			}
 
-			endDate, err := time.Parse(layout, strings.TrimSpace(c.String("end_date")))
+			lands, err := resolveLands(c.StringSlice("land"))
 			if err != nil {
-				return fmt.Errorf("unable to parse end date parameter: %s", err.Error())
+				return err
 			}
 
-			registrations := collectRegistrations(startDate, endDate)
-			if c.String("output") == "jsonl" {
-				for _, r := range registrations {
-					m, _ := json.Marshal(r)
-					fmt.Printf("%s\n", string(m))
+			var logger *crawler.Logger
+			if path := c.String("logger"); path != "" {
+				f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+				if err != nil {
+					return fmt.Errorf("unable to open --logger file: %s", err.Error())
 				}
+				defer f.Close()
+				logger = crawler.NewLogger(f)
+			}
 
-				return nil
+			cr, err := crawler.NewCrawler(crawler.CrawlerOptions{
+				Parallelism:    c.Int("parallelism"),
+				RandomDelay:    c.Duration("random_delay"),
+				CacheDir:       c.String("cache_dir"),
+				CheckpointFile: c.String("checkpoint"),
+				Resume:         c.Bool("resume"),
+				Logger:         logger,
+				Window:         c.Duration("window"),
+				Rubrik:         c.String("rubrik"),
+			})
+			if err != nil {
+				return err
+			}
+
+			sinks := []sink.Sink{}
+			for _, spec := range c.StringSlice("output") {
+				s, err := sink.New(spec, c.String("timestamp_suffix"))
+				if err != nil {
+					return err
+				}
+				sinks = append(sinks, s)
 			}
+			out := sink.Multi(sinks...)
+			defer out.Close()
 
-			if c.String("output") == "csv" {
-				fmt.Println("RegNo;Date;Name;Address;City;PostalCode")
-				for _, r := range registrations {
-					fmt.Printf("%s;%v;%s;%s;%s;%s\n", r.RegNo, r.Date, r.Name, r.Address, r.City, r.PostalCode)
+			ctx := context.Background()
+			for reg := range cr.Run(ctx, startDate, endDate, lands) {
+				if err := out.Write(ctx, reg); err != nil {
+					return err
 				}
-				return nil
 			}
 
-			return errors.New("output not supported")
+			return nil
 		},
 	}
 
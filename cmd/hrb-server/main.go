@@ -0,0 +1,43 @@
+// Command hrb-server runs the crawler as a long-lived HTTP service,
+// exposing on-demand registration streams and webhook-based daily watches
+// instead of a one-shot CLI run.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/crawler"
+	"github.com/cameronnorman/company_registraions_cli/internal/server"
+	"github.com/cameronnorman/company_registraions_cli/internal/store"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "Address to serve HTTP on")
+	storePath := flag.String("store", "hrb-server.db", "SQLite file tracking seen registrations and subscriptions")
+	cacheDir := flag.String("cache_dir", "", "Directory to cache fetched HTML pages in")
+	parallelism := flag.Int("parallelism", 2, "Maximum number of concurrent requests to the Handelsregister domain")
+	randomDelay := flag.Duration("random_delay", 2*time.Second, "Random jitter added between requests to the Handelsregister domain")
+	flag.Parse()
+
+	st, err := store.Open(*storePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer st.Close()
+
+	srv := server.New(crawler.CrawlerOptions{
+		Parallelism: *parallelism,
+		RandomDelay: *randomDelay,
+		CacheDir:    *cacheDir,
+	}, st)
+
+	if err := srv.Start(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("hrb-server listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv.Handler()))
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// checkpointKey identifies a single notice that has already been fetched
+// and parsed, so a resumed crawl can skip it.
+type checkpointKey struct {
+	Date string `json:"date"`
+	Land string `json:"land"`
+	RbID string `json:"rbId"`
+}
+
+// checkpoint is an append-only, newline-delimited-JSON record of
+// checkpointKeys processed so far. It is safe for concurrent use.
+type checkpoint struct {
+	path string
+	seen map[checkpointKey]bool
+	mu   sync.Mutex
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	cp := &checkpoint{path: path, seen: map[checkpointKey]bool{}}
+	if path == "" {
+		return cp, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	for {
+		var key checkpointKey
+		if err := dec.Decode(&key); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		cp.seen[key] = true
+	}
+
+	return cp, nil
+}
+
+func (cp *checkpoint) has(key checkpointKey) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.seen[key]
+}
+
+func (cp *checkpoint) mark(key checkpointKey) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.seen[key] {
+		return nil
+	}
+	cp.seen[key] = true
+
+	if cp.path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(cp.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(key)
+}
@@ -0,0 +1,309 @@
+// Package crawler wraps colly with the rate limiting, caching, retry and
+// checkpointing behaviour needed to run multi-month backfills against
+// handelsregisterbekanntmachungen.de without hammering it or losing
+// progress on a crash.
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/daterange"
+	"github.com/cameronnorman/company_registraions_cli/internal/parser"
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+// CompanyRegistration is a single Handelsregister notice as extracted
+// from the Bekanntmachung HTML.
+type CompanyRegistration = registration.CompanyRegistration
+
+// CrawlerOptions configures a Crawler. Zero values fall back to sane
+// defaults: Parallelism of 2 and MaxRetries of 3.
+type CrawlerOptions struct {
+	// Parallelism caps the number of concurrent requests per domain.
+	Parallelism int
+	// RandomDelay adds jitter between requests to the same domain, on
+	// top of Parallelism, to avoid hammering the site.
+	RandomDelay time.Duration
+	// CacheDir stores fetched HTML on disk so re-runs and retries don't
+	// re-fetch pages that have already been parsed.
+	CacheDir string
+	// CheckpointFile records the (date, land, rb_id) tuples already
+	// processed so a crawl can be resumed with Resume after a crash.
+	CheckpointFile string
+	// Resume, when true, skips any tuple already present in
+	// CheckpointFile instead of re-fetching it.
+	Resume bool
+	// MaxRetries bounds the exponential-backoff retry applied to 5xx
+	// responses and timeouts.
+	MaxRetries int
+	// Logger receives structured JSON log entries for errors and
+	// per-URL timings. A nil Logger falls back to the standard logger for
+	// errors; timings are then not logged.
+	Logger *Logger
+	// Window bounds how much of the requested date range is submitted
+	// per search POST, since the search form only reliably returns
+	// complete results for short spans. Defaults to one day.
+	Window time.Duration
+	// Rubrik restricts the search to a single register rubrik (e.g.
+	// HRA, HRB, GnR, PR, VR). Empty means unrestricted.
+	Rubrik string
+	// OnStatus, if set, is called with the HTTP status code of every
+	// response the crawler receives (0 for transport-level errors where
+	// no response was received), so callers can track the status-code
+	// distribution, e.g. for a /metrics endpoint.
+	OnStatus func(status int)
+	// OnParseError, if set, is called whenever a fetched notice fails to
+	// parse, in addition to the failure being logged.
+	OnParseError func(err error)
+}
+
+// Crawler crawls handelsregisterbekanntmachungen.de for one or more
+// Länder across a date range, streaming parsed registrations as it goes.
+type Crawler struct {
+	opts       CrawlerOptions
+	checkpoint *checkpoint
+}
+
+// NewCrawler builds a Crawler from opts, loading its checkpoint file (if
+// any) so a subsequent Run with Resume set skips already-seen notices.
+func NewCrawler(opts CrawlerOptions) (*Crawler, error) {
+	if opts.Parallelism <= 0 {
+		opts.Parallelism = 2
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.Window <= 0 {
+		opts.Window = 24 * time.Hour
+	}
+
+	cp, err := loadCheckpoint(opts.CheckpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load checkpoint: %s", err.Error())
+	}
+
+	return &Crawler{opts: opts, checkpoint: cp}, nil
+}
+
+var rbIDPattern = regexp.MustCompile(`.*'rb_id=(.*)\&.*`)
+
+// Run crawls every selected land across [start, end], submitting one
+// search query per Window-sized chunk of the range, and streams parsed
+// registrations on the returned channel, deduplicated by RegNo+Date. The
+// channel is closed once the crawl finishes or ctx is cancelled.
+func (cr *Crawler) Run(ctx context.Context, start, end time.Time, lands []string) <-chan CompanyRegistration {
+	out := make(chan CompanyRegistration)
+
+	go func() {
+		defer close(out)
+
+		seen := map[string]bool{}
+		var mu sync.Mutex
+
+		for _, land := range lands {
+			for window := range daterange.Iterate(start, end, cr.opts.Window) {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+					cr.crawlWindow(ctx, window, land, out, seen, &mu)
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+func (cr *Crawler) newCollector() *colly.Collector {
+	c := colly.NewCollector(
+		colly.UserAgent("company_registraions_cli/1.0 (+https://github.com/cameronnorman/company_registraions_cli)"),
+		colly.Async(true),
+	)
+
+	if cr.opts.CacheDir != "" {
+		c.CacheDir = cr.opts.CacheDir
+	}
+
+	c.Limit(&colly.LimitRule{
+		DomainGlob:  "*handelsregisterbekanntmachungen*",
+		Parallelism: cr.opts.Parallelism,
+		RandomDelay: cr.opts.RandomDelay,
+	})
+
+	retries := map[string]int{}
+	var retriesMu sync.Mutex
+	c.OnError(func(r *colly.Response, err error) {
+		if cr.opts.OnStatus != nil {
+			status := 0
+			if r != nil {
+				status = r.StatusCode
+			}
+			cr.opts.OnStatus(status)
+		}
+
+		url := r.Request.URL.String()
+
+		if r.StatusCode != 0 && r.StatusCode < 500 {
+			cr.logError(url, err)
+			return
+		}
+
+		retriesMu.Lock()
+		count := retries[url]
+		retriesMu.Unlock()
+
+		if count >= cr.opts.MaxRetries {
+			cr.logError(url, fmt.Errorf("giving up after %d retries: %s", count, err.Error()))
+			return
+		}
+
+		backoff := time.Duration(math.Pow(2, float64(count))) * time.Second
+		retriesMu.Lock()
+		retries[url]++
+		retriesMu.Unlock()
+		cr.logError(url, fmt.Errorf("retrying in %s: %s", backoff, err.Error()))
+		time.Sleep(backoff)
+
+		if err := r.Request.Retry(); err != nil {
+			cr.logError(url, err)
+		}
+	})
+
+	c.OnRequest(func(r *colly.Request) {
+		r.Ctx.Put("start", time.Now().Format(time.RFC3339Nano))
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		if cr.opts.OnStatus != nil {
+			cr.opts.OnStatus(r.StatusCode)
+		}
+
+		startedAt, err := time.Parse(time.RFC3339Nano, r.Ctx.Get("start"))
+		if err != nil {
+			return
+		}
+		cr.logTiming(r.Request.URL.String(), time.Since(startedAt))
+	})
+
+	return c
+}
+
+func (cr *Crawler) crawlWindow(ctx context.Context, window daterange.DateWindow, land string, out chan<- CompanyRegistration, seen map[string]bool, mu *sync.Mutex) {
+	c := cr.newCollector()
+
+	c.OnHTML("li>a[href]", func(e *colly.HTMLElement) {
+		matches := rbIDPattern.FindStringSubmatch(e.Attr("href"))
+		if len(matches) == 0 {
+			return
+		}
+
+		rbID := matches[1]
+		key := checkpointKey{Date: window.Start.Format("2006-01-02"), Land: land, RbID: rbID}
+		if cr.opts.Resume && cr.checkpoint.has(key) {
+			return
+		}
+
+		regCtx := colly.NewContext()
+		regCtx.Put("rbId", rbID)
+		regCtx.Put("land", land)
+		regCtx.Put("date", key.Date)
+
+		regURL := fmt.Sprintf("https://www.handelsregisterbekanntmachungen.de/skripte/hrb.php?rb_id=%s&land_abk=%s", rbID, land)
+		if err := c.Request("GET", regURL, nil, regCtx, nil); err != nil {
+			cr.logError(regURL, err)
+		}
+	})
+
+	c.OnHTML("font", func(e *colly.HTMLElement) {
+		html, err := goquery.OuterHtml(e.DOM)
+		if err != nil {
+			cr.logError(e.Request.URL.String(), err)
+			return
+		}
+
+		reg, err := parser.Parse(strings.NewReader(html))
+		if err != nil {
+			cr.logError(e.Request.URL.String(), err)
+			if cr.opts.OnParseError != nil {
+				cr.opts.OnParseError(err)
+			}
+			return
+		}
+
+		dedupeKey := reg.RegNo
+		if reg.Date != nil {
+			dedupeKey += "|" + reg.Date.Format("2006-01-02")
+		}
+
+		mu.Lock()
+		duplicate := seen[dedupeKey]
+		seen[dedupeKey] = true
+		mu.Unlock()
+
+		if !duplicate {
+			select {
+			case out <- reg:
+			case <-ctx.Done():
+			}
+		}
+
+		if rbID := e.Request.Ctx.Get("rbId"); rbID != "" {
+			key := checkpointKey{Date: e.Request.Ctx.Get("date"), Land: e.Request.Ctx.Get("land"), RbID: rbID}
+			if err := cr.checkpoint.mark(key); err != nil {
+				cr.logError(e.Request.URL.String(), fmt.Errorf("unable to write checkpoint: %s", err.Error()))
+			}
+		}
+	})
+
+	data := map[string]string{
+		"suchart":      "uneingeschr",
+		"button":       "Suche+starten",
+		"vt":           fmt.Sprintf("%d", window.Start.Day()),
+		"vm":           fmt.Sprintf("%d", window.Start.Month()),
+		"vj":           fmt.Sprintf("%d", window.Start.Year()),
+		"bt":           fmt.Sprintf("%d", window.End.Day()),
+		"bm":           fmt.Sprintf("%d", window.End.Month()),
+		"bj":           fmt.Sprintf("%d", window.End.Year()),
+		"land":         land,
+		"gericht":      "",
+		"gericht_name": "",
+		"seite":        "",
+		"l":            "",
+		"r":            "",
+		"all":          "false",
+		"rubrik":       cr.opts.Rubrik,
+		"az":           "",
+		"gegenstand":   "0",
+		"order":        "4",
+	}
+
+	if err := c.Post("https://www.handelsregisterbekanntmachungen.de/?aktion=suche#Ergebnis", data); err != nil {
+		cr.logError("https://www.handelsregisterbekanntmachungen.de/?aktion=suche", err)
+	}
+	c.Wait()
+}
+
+func (cr *Crawler) logError(url string, err error) {
+	if cr.opts.Logger != nil {
+		cr.opts.Logger.Error(url, err)
+		return
+	}
+	log.Println(err.Error())
+}
+
+func (cr *Crawler) logTiming(url string, d time.Duration) {
+	if cr.opts.Logger != nil {
+		cr.opts.Logger.Timing(url, d)
+	}
+}
@@ -0,0 +1,46 @@
+package crawler
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+type logEntry struct {
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+	URL      string    `json:"url,omitempty"`
+	Duration string    `json:"duration,omitempty"`
+}
+
+// Logger writes structured JSON log entries, one per line, for crawl
+// errors and per-URL request timings. It is safe for concurrent use.
+type Logger struct {
+	enc *json.Encoder
+	mu  sync.Mutex
+}
+
+// NewLogger returns a Logger that writes newline-delimited JSON to w.
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{enc: json.NewEncoder(w)}
+}
+
+// Error records a crawl error encountered while fetching url.
+func (l *Logger) Error(url string, err error) {
+	l.write(logEntry{Level: "error", Message: err.Error(), URL: url})
+}
+
+// Timing records how long a request to url took to complete.
+func (l *Logger) Timing(url string, d time.Duration) {
+	l.write(logEntry{Level: "info", Message: "request completed", URL: url, Duration: d.String()})
+}
+
+func (l *Logger) write(entry logEntry) {
+	entry.Time = time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(entry)
+}
@@ -0,0 +1,123 @@
+// Package daterange parses and iterates over the date ranges used to
+// window Handelsregister search queries, since the search form only
+// reliably returns complete results for short windows.
+package daterange
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+)
+
+// DateWindow is a single inclusive [Start, End] window to submit as one
+// search query.
+type DateWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Iterate yields consecutive DateWindows covering [start, end], each
+// spanning step (rounded down to a whole number of days, minimum one
+// day). The final window is clamped to end. Windows are computed with
+// calendar day arithmetic so they land on the correct wall-clock date
+// across DST transitions and month/year boundaries.
+func Iterate(start, end time.Time, step time.Duration) iter.Seq[DateWindow] {
+	days := int(step / (24 * time.Hour))
+	if days < 1 {
+		days = 1
+	}
+
+	return func(yield func(DateWindow) bool) {
+		cursor := start
+		for !cursor.After(end) {
+			windowEnd := cursor.AddDate(0, 0, days-1)
+			if windowEnd.After(end) {
+				windowEnd = end
+			}
+
+			if !yield(DateWindow{Start: cursor, End: windowEnd}) {
+				return
+			}
+
+			cursor = windowEnd.AddDate(0, 0, 1)
+		}
+	}
+}
+
+var dateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date %q: expected YYYY-MM-DD or DD.MM.YYYY", s)
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// Parse interprets a user-supplied date range expression and returns its
+// start and end, both inclusive.
+//
+// Supported forms: a single "YYYY-MM-DD" or "DD.MM.YYYY" date (start ==
+// end); "start..end" where either side may be blank for an open-ended
+// range (e.g. "..2024-06-30" or "2024-01-01.."), in which case a
+// missing start is the zero time (check with time.Time.IsZero) and a
+// missing end defaults to today; and the relative keywords "today",
+// "yesterday", "last-week" and "last-month".
+func Parse(s string) (time.Time, time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	switch s {
+	case "today":
+		d := today()
+		return d, d, nil
+	case "yesterday":
+		d := today().AddDate(0, 0, -1)
+		return d, d, nil
+	case "last-week":
+		end := today().AddDate(0, 0, -1)
+		return end.AddDate(0, 0, -6), end, nil
+	case "last-month":
+		end := today()
+		return end.AddDate(0, -1, 0), end, nil
+	}
+
+	if strings.Contains(s, "..") {
+		parts := strings.SplitN(s, "..", 2)
+		startStr, endStr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		start := time.Time{}
+		if startStr != "" {
+			parsed, err := parseDate(startStr)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			start = parsed
+		}
+
+		end := today()
+		if endStr != "" {
+			parsed, err := parseDate(endStr)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			end = parsed
+		}
+
+		return start, end, nil
+	}
+
+	d, err := parseDate(s)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return d, d, nil
+}
@@ -0,0 +1,131 @@
+package daterange
+
+import (
+	"testing"
+	"time"
+)
+
+func collect(start, end time.Time, step time.Duration) []DateWindow {
+	windows := []DateWindow{}
+	for w := range Iterate(start, end, step) {
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+func TestIterateDaily(t *testing.T) {
+	start := time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)
+
+	windows := collect(start, end, 24*time.Hour)
+	if len(windows) != 4 {
+		t.Fatalf("expected 4 daily windows across the month boundary, got %d", len(windows))
+	}
+	if !windows[0].Start.Equal(start) {
+		t.Errorf("expected first window to start on %s, got %s", start, windows[0].Start)
+	}
+	if !windows[len(windows)-1].End.Equal(end) {
+		t.Errorf("expected last window to end on %s, got %s", end, windows[len(windows)-1].End)
+	}
+}
+
+func TestIterateWeeklyClampsFinalWindow(t *testing.T) {
+	start := time.Date(2023, time.December, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+	windows := collect(start, end, 7*24*time.Hour)
+	if len(windows) != 3 {
+		t.Fatalf("expected 3 weekly windows across the year boundary, got %d", len(windows))
+	}
+	last := windows[len(windows)-1]
+	if !last.End.Equal(end) {
+		t.Errorf("expected final window to be clamped to %s, got %s", end, last.End)
+	}
+	if last.End.Before(last.Start) {
+		t.Errorf("clamped window has end %s before start %s", last.End, last.Start)
+	}
+}
+
+func TestIterateAcrossDSTTransition(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("tzdata not available: %s", err.Error())
+	}
+
+	// 2024-03-31 is when Germany springs forward; local midnight still
+	// exists on both sides so calendar-day stepping must not skip or
+	// repeat a day around the transition.
+	start := time.Date(2024, time.March, 29, 0, 0, 0, 0, berlin)
+	end := time.Date(2024, time.April, 1, 0, 0, 0, 0, berlin)
+
+	windows := collect(start, end, 24*time.Hour)
+	if len(windows) != 4 {
+		t.Fatalf("expected 4 daily windows across the DST transition, got %d", len(windows))
+	}
+
+	wantDays := []int{29, 30, 31, 1}
+	for i, w := range windows {
+		if w.Start.Day() != wantDays[i] {
+			t.Errorf("window %d: expected day %d, got %d", i, wantDays[i], w.Start.Day())
+		}
+		if w.Start.Hour() != 0 {
+			t.Errorf("window %d: expected midnight local time, got hour %d", i, w.Start.Hour())
+		}
+	}
+}
+
+func TestParseAbsoluteDates(t *testing.T) {
+	cases := []string{"2024-06-30", "30.06.2024"}
+	for _, s := range cases {
+		start, end, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %s", s, err.Error())
+		}
+		want := time.Date(2024, time.June, 30, 0, 0, 0, 0, start.Location())
+		if !start.Equal(want) || !end.Equal(want) {
+			t.Errorf("Parse(%q) = %s..%s, want %s", s, start, end, want)
+		}
+	}
+}
+
+func TestParseOpenEnded(t *testing.T) {
+	start, end, err := Parse("2024-01-01..")
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+	if !start.Equal(time.Date(2024, time.January, 1, 0, 0, 0, 0, start.Location())) {
+		t.Errorf("unexpected start: %s", start)
+	}
+	if end.IsZero() {
+		t.Errorf("expected an open-ended end date to default to today, got zero time")
+	}
+
+	start, end, err = Parse("..2024-06-30")
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+	if !start.IsZero() {
+		t.Errorf("expected an open-ended start to be the zero time, got %s", start)
+	}
+	if !end.Equal(time.Date(2024, time.June, 30, 0, 0, 0, 0, end.Location())) {
+		t.Errorf("unexpected end: %s", end)
+	}
+}
+
+func TestParseRelativeKeywords(t *testing.T) {
+	for _, s := range []string{"today", "yesterday", "last-week", "last-month"} {
+		start, end, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) returned error: %s", s, err.Error())
+		}
+		if end.Before(start) {
+			t.Errorf("Parse(%q) = %s..%s, end before start", s, start, end)
+		}
+	}
+}
+
+func TestParseInvalidDate(t *testing.T) {
+	if _, _, err := Parse("not-a-date"); err == nil {
+		t.Fatal("expected an error for an unparseable date, got nil")
+	}
+}
@@ -0,0 +1,192 @@
+// Package parser extracts a registration.CompanyRegistration from a
+// single Bekanntmachung notice's HTML. It walks the <font>/<tr> table
+// structure with goquery instead of blindly indexing comma-split text,
+// so company names containing commas, c/o addresses and foreign
+// postcodes no longer produce garbage or panic.
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+// Field names a CompanyRegistration field a ParseError failed to
+// extract.
+type Field string
+
+const (
+	FieldCourt        Field = "court"
+	FieldRegisterKind Field = "registerKind"
+	FieldNoticeType   Field = "noticeType"
+	FieldDate         Field = "date"
+	FieldName         Field = "name"
+)
+
+// ParseError reports which field failed to extract, the raw text it was
+// extracted from, and the underlying cause.
+type ParseError struct {
+	Field   Field
+	RawText string
+	Cause   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parser: unable to extract %s from %q: %s", e.Field, e.RawText, e.Cause.Error())
+}
+
+func (e *ParseError) Unwrap() error { return e.Cause }
+
+var (
+	courtPattern            = regexp.MustCompile(`Amtsgericht\s+(.+?)\s+(?:HRA|HRB|GnR|PR|VR)\b`)
+	registerPattern         = regexp.MustCompile(`(HRA|HRB|GnR|PR|VR)\s*(\d+)`)
+	noticeTypePattern       = regexp.MustCompile(`(Neueintragung|Veränderung|Löschung)`)
+	registrationDatePattern = regexp.MustCompile(`Bekannt gemacht am:(.*)Uhr`)
+	postalCodePattern       = regexp.MustCompile(`\d{5}`)
+)
+
+// Parse reads a single notice's HTML (the <font> block colly's "font"
+// selector matches) and extracts a CompanyRegistration from it.
+func Parse(r io.Reader) (registration.CompanyRegistration, error) {
+	reg := registration.CompanyRegistration{}
+
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return reg, fmt.Errorf("parser: unable to parse HTML: %s", err.Error())
+	}
+
+	rows := doc.Find("font tr")
+	if rows.Length() == 0 {
+		rows = doc.Find("tr")
+	}
+	if rows.Length() == 0 {
+		return reg, &ParseError{Field: FieldCourt, Cause: errors.New("no <tr> rows found")}
+	}
+
+	headerCells := rows.Eq(0).Find("td")
+	if headerCells.Length() < 2 {
+		return reg, &ParseError{Field: FieldDate, RawText: strings.TrimSpace(rows.Eq(0).Text()), Cause: errors.New("expected two header cells")}
+	}
+
+	metaText := strings.TrimSpace(headerCells.Eq(0).Text())
+	dateText := strings.TrimSpace(headerCells.Eq(1).Text())
+
+	court, err := extractCourt(metaText)
+	if err != nil {
+		return reg, &ParseError{Field: FieldCourt, RawText: metaText, Cause: err}
+	}
+	reg.Court = court
+
+	kind, number, err := extractRegister(metaText)
+	if err != nil {
+		return reg, &ParseError{Field: FieldRegisterKind, RawText: metaText, Cause: err}
+	}
+	reg.RegisterKind = kind
+	reg.RegNo = fmt.Sprintf("%s %s", kind, number)
+
+	noticeType, err := extractNoticeType(metaText)
+	if err != nil {
+		return reg, &ParseError{Field: FieldNoticeType, RawText: metaText, Cause: err}
+	}
+	reg.NoticeType = noticeType
+
+	date, err := extractRegistrationDate(dateText)
+	if err != nil {
+		return reg, &ParseError{Field: FieldDate, RawText: dateText, Cause: err}
+	}
+	reg.Date = date
+
+	detail := findDetailRow(rows)
+	if detail == nil {
+		return reg, &ParseError{Field: FieldName, Cause: errors.New("no company detail row found")}
+	}
+
+	cells := detail.Find("td")
+	reg.Name = strings.TrimSpace(cells.Eq(0).Text())
+	reg.City = strings.TrimSpace(cells.Eq(1).Text())
+	reg.PostalCode, reg.Address = splitPostalCode(strings.TrimSpace(cells.Eq(2).Text()))
+
+	return reg, nil
+}
+
+// findDetailRow returns the first row with exactly three cells: company
+// name, city and postal-code-plus-address. Matching by cell count rather
+// than a fixed row index tolerates notices with a varying number of
+// header rows.
+func findDetailRow(rows *goquery.Selection) *goquery.Selection {
+	var detail *goquery.Selection
+
+	rows.EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if s.Find("td").Length() == 3 {
+			detail = s
+			return false
+		}
+		return true
+	})
+
+	return detail
+}
+
+func extractCourt(text string) (string, error) {
+	matches := courtPattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return "", errors.New("no Amtsgericht found")
+	}
+
+	return strings.TrimSpace(matches[1]), nil
+}
+
+func extractRegister(text string) (kind string, number string, err error) {
+	matches := registerPattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return "", "", errors.New("no register reference found")
+	}
+
+	return matches[1], matches[2], nil
+}
+
+func extractNoticeType(text string) (string, error) {
+	matches := noticeTypePattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return "", errors.New("no notice type found")
+	}
+
+	return matches[1], nil
+}
+
+func extractRegistrationDate(text string) (*time.Time, error) {
+	matches := registrationDatePattern.FindStringSubmatch(text)
+	if len(matches) == 0 {
+		return nil, errors.New("no registration date found")
+	}
+
+	t, err := time.Parse("02.01.2006 15:04", strings.TrimSpace(matches[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// splitPostalCode pulls a German 5-digit postal code out of text and
+// returns it alongside the remaining address. Foreign addresses without
+// a 5-digit code are returned with an empty postal code rather than an
+// error, since that's a legitimate notice, not a parse failure.
+func splitPostalCode(text string) (postalCode string, address string) {
+	loc := postalCodePattern.FindStringIndex(text)
+	if loc == nil {
+		return "", text
+	}
+
+	postalCode = text[loc[0]:loc[1]]
+	address = strings.TrimSpace(text[:loc[0]] + text[loc[1]:])
+
+	return postalCode, address
+}
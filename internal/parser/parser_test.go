@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func loadFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("unable to open fixture %s: %s", name, err.Error())
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
+
+func TestParseNeueintragung(t *testing.T) {
+	reg, err := Parse(loadFixture(t, "neueintragung.html"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+
+	if reg.NoticeType != "Neueintragung" {
+		t.Errorf("NoticeType = %q, want Neueintragung", reg.NoticeType)
+	}
+	if reg.Court != "Stuttgart" {
+		t.Errorf("Court = %q, want Stuttgart", reg.Court)
+	}
+	if reg.RegisterKind != "HRB" {
+		t.Errorf("RegisterKind = %q, want HRB", reg.RegisterKind)
+	}
+	if reg.RegNo != "HRB 123456" {
+		t.Errorf("RegNo = %q, want %q", reg.RegNo, "HRB 123456")
+	}
+	if reg.Name != "Musterfirma, Handel & Consulting GmbH" {
+		t.Errorf("Name = %q, want a name with its comma preserved", reg.Name)
+	}
+	if reg.City != "Stuttgart" {
+		t.Errorf("City = %q, want Stuttgart", reg.City)
+	}
+	if reg.PostalCode != "70173" {
+		t.Errorf("PostalCode = %q, want 70173", reg.PostalCode)
+	}
+	if reg.Address != "Musterstr. 1" {
+		t.Errorf("Address = %q, want %q", reg.Address, "Musterstr. 1")
+	}
+	if reg.Date == nil || !reg.Date.Equal(time.Date(2024, time.January, 2, 9, 15, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want 2024-01-02 09:15", reg.Date)
+	}
+}
+
+func TestParseVeraenderung(t *testing.T) {
+	reg, err := Parse(loadFixture(t, "veraenderung.html"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+
+	if reg.NoticeType != "Veränderung" {
+		t.Errorf("NoticeType = %q, want Veränderung", reg.NoticeType)
+	}
+	if reg.RegisterKind != "HRA" {
+		t.Errorf("RegisterKind = %q, want HRA", reg.RegisterKind)
+	}
+	if reg.PostalCode != "10115" {
+		t.Errorf("PostalCode = %q, want 10115", reg.PostalCode)
+	}
+	if reg.Address != "c/o Schmidt, Unter den Linden 5" {
+		t.Errorf("Address = %q, want a c/o address with its comma preserved", reg.Address)
+	}
+}
+
+func TestParseLoeschungWithForeignAddress(t *testing.T) {
+	reg, err := Parse(loadFixture(t, "loeschung.html"))
+	if err != nil {
+		t.Fatalf("Parse returned error: %s", err.Error())
+	}
+
+	if reg.NoticeType != "Löschung" {
+		t.Errorf("NoticeType = %q, want Löschung", reg.NoticeType)
+	}
+	if reg.PostalCode != "" {
+		t.Errorf("PostalCode = %q, want empty for a foreign address with no 5-digit code", reg.PostalCode)
+	}
+	if reg.Address != "Rue de Genève 12, 1201 Genf, Schweiz" {
+		t.Errorf("Address = %q, want the full foreign address untouched", reg.Address)
+	}
+}
+
+func TestParseReturnsTypedErrorOnMissingRows(t *testing.T) {
+	_, err := Parse(strings.NewReader("<html><body>no table here</body></html>"))
+	if err == nil {
+		t.Fatal("expected an error when no <tr> rows are present")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a *ParseError, got %T: %s", err, err.Error())
+	}
+}
@@ -0,0 +1,20 @@
+// Package registration holds the CompanyRegistration record shared by
+// the crawler, parser and sink packages, so none of them need to import
+// one another just to pass the type around.
+package registration
+
+import "time"
+
+// CompanyRegistration is a single Handelsregister notice as extracted
+// from the Bekanntmachung HTML.
+type CompanyRegistration struct {
+	RegNo        string     `json:"regno"`
+	Date         *time.Time `json:"date"`
+	Address      string     `json:"address"`
+	City         string     `json:"city"`
+	PostalCode   string     `json:"postalCode"`
+	Name         string     `json:"name"`
+	NoticeType   string     `json:"noticeType"`
+	Court        string     `json:"court"`
+	RegisterKind string     `json:"registerKind"`
+}
@@ -0,0 +1,283 @@
+// Package server exposes the crawler over HTTP: a GET /registrations
+// stream, a POST /watch endpoint for daily diffed polling with webhook
+// push, and a Prometheus /metrics endpoint, so other services can
+// subscribe to the crawl instead of running their own.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/robfig/cron/v3"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/crawler"
+	"github.com/cameronnorman/company_registraions_cli/internal/daterange"
+	"github.com/cameronnorman/company_registraions_cli/internal/sink"
+	"github.com/cameronnorman/company_registraions_cli/internal/store"
+)
+
+// crawlerRunner is the subset of *crawler.Crawler the server depends on.
+// Tests substitute a fake implementation so handler/poll behaviour can be
+// exercised without hitting handelsregisterbekanntmachungen.de.
+type crawlerRunner interface {
+	Run(ctx context.Context, start, end time.Time, lands []string) <-chan crawler.CompanyRegistration
+}
+
+// metrics holds its own registry rather than registering against the
+// global default one, so multiple Servers (e.g. one per test) can coexist
+// in the same process without colliding on metric names.
+type metrics struct {
+	registry      *prometheus.Registry
+	registrations prometheus.Counter
+	parseErrors   prometheus.Counter
+	httpStatus    *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	registry := prometheus.NewRegistry()
+	factory := promauto.With(registry)
+
+	return &metrics{
+		registry: registry,
+		registrations: factory.NewCounter(prometheus.CounterOpts{
+			Name: "hrb_registrations_total",
+			Help: "Number of registrations streamed from /registrations or pushed as new to a watch webhook.",
+		}),
+		parseErrors: factory.NewCounter(prometheus.CounterOpts{
+			Name: "hrb_parse_errors_total",
+			Help: "Number of notices that failed to parse.",
+		}),
+		httpStatus: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "hrb_http_status_total",
+			Help: "Responses from handelsregisterbekanntmachungen.de by status class, as seen by colly.",
+		}, []string{"class"}),
+	}
+}
+
+// Server exposes a Crawler over HTTP.
+type Server struct {
+	baseOpts       crawler.CrawlerOptions
+	store          *store.Store
+	metrics        *metrics
+	cron           *cron.Cron
+	crawlerFactory func(rubrik string) (crawlerRunner, error)
+}
+
+// New builds a Server. baseOpts configures every Crawler the server
+// creates, except Rubrik, which is overridden per request/subscription,
+// and OnStatus and OnParseError, which the server sets to feed /metrics.
+// st persists seen registrations and subscriptions across restarts.
+func New(baseOpts crawler.CrawlerOptions, st *store.Store) *Server {
+	s := &Server{
+		baseOpts: baseOpts,
+		store:    st,
+		metrics:  newMetrics(),
+		cron:     cron.New(),
+	}
+	s.crawlerFactory = s.newCrawler
+	return s
+}
+
+// Handler returns the server's http.Handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/registrations", s.handleRegistrations)
+	mux.HandleFunc("/watch", s.handleWatch)
+	mux.Handle("/metrics", promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+// Start resumes any subscriptions already persisted in the store (e.g.
+// from before a restart) and starts the cron scheduler. Call it before
+// serving requests.
+func (s *Server) Start() error {
+	subs, err := s.store.Subscriptions()
+	if err != nil {
+		return fmt.Errorf("server: unable to load subscriptions: %s", err.Error())
+	}
+
+	for _, sub := range subs {
+		if err := s.schedule(sub); err != nil {
+			return err
+		}
+	}
+
+	s.cron.Start()
+	return nil
+}
+
+func (s *Server) newCrawler(rubrik string) (crawlerRunner, error) {
+	opts := s.baseOpts
+	opts.Rubrik = rubrik
+	opts.OnStatus = func(status int) {
+		class := "0xx"
+		if status > 0 {
+			class = fmt.Sprintf("%dxx", status/100)
+		}
+		s.metrics.httpStatus.WithLabelValues(class).Inc()
+	}
+	opts.OnParseError = func(error) {
+		s.metrics.parseErrors.Inc()
+	}
+
+	return crawler.NewCrawler(opts)
+}
+
+func (s *Server) handleRegistrations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	dateRange := orDefault(q.Get("range"), "today")
+	start, end, err := daterange.Parse(dateRange)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if start.IsZero() || end.IsZero() {
+		http.Error(w, fmt.Sprintf("range %q is open-ended on one side; supply both a start and an end", dateRange), http.StatusBadRequest)
+		return
+	}
+
+	lands := []string{"bw"}
+	if land := q.Get("land"); land != "" {
+		lands = strings.Split(land, ",")
+	}
+
+	format := orDefault(q.Get("format"), "jsonl")
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+
+	out, err := sink.NewWriter(w, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cr, err := s.crawlerFactory(q.Get("rubrik"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for reg := range cr.Run(r.Context(), start, end, lands) {
+		s.metrics.registrations.Inc()
+		if err := out.Write(r.Context(), reg); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+type watchRequest struct {
+	Webhook string   `json:"webhook"`
+	Cron    string   `json:"cron"`
+	Lands   []string `json:"land"`
+	Rubrik  string   `json:"rubrik"`
+}
+
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req watchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if req.Webhook == "" || req.Cron == "" {
+		http.Error(w, "webhook and cron are required", http.StatusBadRequest)
+		return
+	}
+
+	sub := store.Subscription{Webhook: req.Webhook, Cron: req.Cron, Lands: req.Lands, Rubrik: req.Rubrik}
+
+	id, err := s.store.AddSubscription(sub)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sub.ID = id
+
+	if err := s.schedule(sub); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int64{"id": id})
+}
+
+func (s *Server) schedule(sub store.Subscription) error {
+	_, err := s.cron.AddFunc(sub.Cron, func() {
+		s.poll(sub)
+	})
+	if err != nil {
+		return fmt.Errorf("server: invalid cron expression %q: %s", sub.Cron, err.Error())
+	}
+
+	return nil
+}
+
+// poll crawls today's notices for sub and pushes only those not already
+// recorded in the store to sub's webhook.
+func (s *Server) poll(sub store.Subscription) {
+	cr, err := s.crawlerFactory(sub.Rubrik)
+	if err != nil {
+		return
+	}
+
+	lands := sub.Lands
+	if len(lands) == 0 {
+		lands = []string{"bw"}
+	}
+
+	webhook, err := sink.New("webhook:"+sub.Webhook, "")
+	if err != nil {
+		return
+	}
+	defer webhook.Close()
+
+	today, _, err := daterange.Parse("today")
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	for reg := range cr.Run(ctx, today, today, lands) {
+		seen, err := s.store.Seen(reg.RegNo, reg.Court)
+		if err != nil || seen {
+			continue
+		}
+
+		if err := webhook.Write(ctx, reg); err != nil {
+			continue
+		}
+
+		if _, err := s.store.MarkSeen(reg.RegNo, reg.Court); err != nil {
+			continue
+		}
+		s.metrics.registrations.Inc()
+	}
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
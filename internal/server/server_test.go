@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/crawler"
+	"github.com/cameronnorman/company_registraions_cli/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.Open returned error: %s", err.Error())
+	}
+	t.Cleanup(func() { st.Close() })
+
+	return st
+}
+
+// stubCrawler replays a fixed list of registrations instead of crawling
+// handelsregisterbekanntmachungen.de, so handler/poll tests can run
+// without network access.
+type stubCrawler struct {
+	regs  []crawler.CompanyRegistration
+	delay time.Duration
+	// done, if set, is closed when the Run goroutine returns, so tests
+	// can observe that it unwound instead of leaking blocked on a send.
+	done chan struct{}
+}
+
+func (c *stubCrawler) Run(ctx context.Context, start, end time.Time, lands []string) <-chan crawler.CompanyRegistration {
+	out := make(chan crawler.CompanyRegistration)
+
+	go func() {
+		defer close(out)
+		if c.done != nil {
+			defer close(c.done)
+		}
+		for _, reg := range c.regs {
+			if c.delay > 0 {
+				select {
+				case <-time.After(c.delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case out <- reg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func TestPollMarksSeenOnlyAfterSuccessfulWebhookPush(t *testing.T) {
+	var received []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reg crawler.CompanyRegistration
+		if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+			t.Fatalf("webhook received undecodable body: %s", err.Error())
+		}
+		received = append(received, reg.RegNo)
+
+		if reg.RegNo == "HRB 2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	s := New(crawler.CrawlerOptions{}, newTestStore(t))
+	s.crawlerFactory = func(rubrik string) (crawlerRunner, error) {
+		return &stubCrawler{regs: []crawler.CompanyRegistration{
+			{RegNo: "HRB 1", Court: "Stuttgart"},
+			{RegNo: "HRB 2", Court: "Stuttgart"},
+		}}, nil
+	}
+
+	s.poll(store.Subscription{Webhook: webhook.URL})
+
+	if len(received) != 2 {
+		t.Fatalf("webhook received %d requests, want 2: %v", len(received), received)
+	}
+
+	seen, err := s.store.Seen("HRB 1", "Stuttgart")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if !seen {
+		t.Error("HRB 1 was pushed successfully but not marked seen")
+	}
+
+	seen, err = s.store.Seen("HRB 2", "Stuttgart")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if seen {
+		t.Error("HRB 2's webhook push failed but it was marked seen anyway, it will never be retried")
+	}
+}
+
+func TestPollSkipsAlreadySeenRegistrations(t *testing.T) {
+	var pushed int
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pushed++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+
+	st := newTestStore(t)
+	if _, err := st.MarkSeen("HRB 1", "Stuttgart"); err != nil {
+		t.Fatalf("MarkSeen returned error: %s", err.Error())
+	}
+
+	s := New(crawler.CrawlerOptions{}, st)
+	s.crawlerFactory = func(rubrik string) (crawlerRunner, error) {
+		return &stubCrawler{regs: []crawler.CompanyRegistration{
+			{RegNo: "HRB 1", Court: "Stuttgart"},
+		}}, nil
+	}
+
+	s.poll(store.Subscription{Webhook: webhook.URL})
+
+	if pushed != 0 {
+		t.Errorf("poll pushed an already-seen registration to the webhook")
+	}
+}
+
+func TestHandleRegistrationsStopsCrawlOnClientDisconnect(t *testing.T) {
+	done := make(chan struct{})
+
+	s := New(crawler.CrawlerOptions{}, newTestStore(t))
+	s.crawlerFactory = func(rubrik string) (crawlerRunner, error) {
+		return &stubCrawler{
+			delay: 50 * time.Millisecond,
+			done:  done,
+			regs: []crawler.CompanyRegistration{
+				{RegNo: "HRB 1", Court: "Stuttgart"},
+				{RegNo: "HRB 2", Court: "Stuttgart"},
+				{RegNo: "HRB 3", Court: "Stuttgart"},
+			},
+		}, nil
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/registrations?range=today", nil)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req = req.WithContext(ctx)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request returned error: %s", err.Error())
+	}
+
+	// Read the first registration off the stream, then disconnect
+	// mid-crawl, before the stub has sent the remaining two.
+	buf := make([]byte, 1)
+	if _, err := resp.Body.Read(buf); err != nil {
+		t.Fatalf("unable to read from response: %s", err.Error())
+	}
+	resp.Body.Close()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("crawl goroutine is still blocked after the client disconnected; it leaked")
+	}
+}
@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+var csvHeader = []string{"RegNo", "Date", "Name", "Address", "City", "PostalCode", "NoticeType", "Court", "RegisterKind"}
+
+// delimitedSink writes registrations through encoding/csv, which quotes
+// and escapes fields containing the delimiter or a newline, unlike the
+// bare fmt.Printf formatting it replaces.
+type delimitedSink struct {
+	w         *csv.Writer
+	closer    io.Closer
+	wroteHead bool
+}
+
+func newDelimitedSink(w io.Writer, delimiter rune) *delimitedSink {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	return &delimitedSink{w: cw}
+}
+
+func newFileDelimitedSink(path string, delimiter rune) (*delimitedSink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("sink: unable to create %s: %s", path, err.Error())
+	}
+
+	s := newDelimitedSink(f, delimiter)
+	s.closer = f
+	return s, nil
+}
+
+func (s *delimitedSink) Write(_ context.Context, reg registration.CompanyRegistration) error {
+	if !s.wroteHead {
+		if err := s.w.Write(csvHeader); err != nil {
+			return err
+		}
+		s.wroteHead = true
+	}
+
+	var date string
+	if reg.Date != nil {
+		date = reg.Date.Format(time.RFC3339)
+	}
+
+	record := []string{reg.RegNo, date, reg.Name, reg.Address, reg.City, reg.PostalCode, reg.NoticeType, reg.Court, reg.RegisterKind}
+	if err := s.w.Write(record); err != nil {
+		return err
+	}
+
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *delimitedSink) Close() error {
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return err
+	}
+
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+func TestDelimitedSinkQuotesEmbeddedDelimiterAndNewline(t *testing.T) {
+	var buf bytes.Buffer
+	s := newDelimitedSink(&buf, ',')
+
+	reg := registration.CompanyRegistration{
+		RegNo: "HRB 123456",
+		Name:  "Musterfirma, Handel & Consulting GmbH\nc/o Muster Treuhand",
+	}
+	if err := s.Write(context.Background(), reg); err != nil {
+		t.Fatalf("Write returned error: %s", err.Error())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned error: %s", err.Error())
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("unable to read back written CSV: %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header row and one data row, got %d rows", len(rows))
+	}
+
+	nameCol := -1
+	for i, h := range rows[0] {
+		if h == "Name" {
+			nameCol = i
+		}
+	}
+	if nameCol == -1 {
+		t.Fatalf("header %v has no Name column", rows[0])
+	}
+
+	if got := rows[1][nameCol]; got != reg.Name {
+		t.Errorf("Name round-tripped as %q, want %q", got, reg.Name)
+	}
+}
@@ -0,0 +1,43 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+type jsonlSink struct {
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+func newJSONLSink(w io.Writer) *jsonlSink {
+	return &jsonlSink{enc: json.NewEncoder(w)}
+}
+
+func newFileJSONLSink(path string) (*jsonlSink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("sink: unable to create %s: %s", path, err.Error())
+	}
+
+	s := newJSONLSink(f)
+	s.closer = f
+	return s, nil
+}
+
+func (s *jsonlSink) Write(_ context.Context, reg registration.CompanyRegistration) error {
+	return s.enc.Encode(reg)
+}
+
+func (s *jsonlSink) Close() error {
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package sink defines pluggable destinations for parsed company
+// registrations, so a long crawl can stream results to disk, a
+// database or a webhook as they're extracted instead of buffering
+// everything in memory until the crawl finishes.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+// Sink receives parsed registrations as a crawl streams them.
+type Sink interface {
+	Write(ctx context.Context, reg registration.CompanyRegistration) error
+	Close() error
+}
+
+// New builds a Sink from a spec of the form "scheme:target":
+// "csv:out.csv", "jsonl:out.jsonl", "sqlite:out.db",
+// "webhook:https://example.com/hook", "stdout:csv" or "stdout:jsonl".
+// timestampSuffix, if non-empty, is inserted before a file sink's
+// extension so repeated runs don't clobber each other's output.
+func New(spec string, timestampSuffix string) (Sink, error) {
+	scheme, target, ok := strings.Cut(spec, ":")
+	if !ok {
+		return nil, fmt.Errorf("sink: invalid --output %q, expected scheme:target", spec)
+	}
+
+	switch scheme {
+	case "csv":
+		return newFileDelimitedSink(withTimestamp(target, timestampSuffix), ',')
+	case "jsonl":
+		return newFileJSONLSink(withTimestamp(target, timestampSuffix))
+	case "sqlite":
+		return newSQLiteSink(withTimestamp(target, timestampSuffix))
+	case "webhook":
+		return newWebhookSink(target), nil
+	case "stdout":
+		switch target {
+		case "csv":
+			return newDelimitedSink(os.Stdout, ','), nil
+		case "jsonl":
+			return newJSONLSink(os.Stdout), nil
+		default:
+			return nil, fmt.Errorf("sink: unknown stdout format %q, expected csv or jsonl", target)
+		}
+	default:
+		return nil, fmt.Errorf("sink: unknown scheme %q, expected csv, jsonl, sqlite, webhook or stdout", scheme)
+	}
+}
+
+// NewWriter builds a Sink that writes directly to w in the given format
+// ("csv" or "jsonl"), for callers that already have an io.Writer (e.g.
+// an HTTP response body) rather than a file path.
+func NewWriter(w io.Writer, format string) (Sink, error) {
+	switch format {
+	case "csv":
+		return newDelimitedSink(w, ','), nil
+	case "jsonl":
+		return newJSONLSink(w), nil
+	default:
+		return nil, fmt.Errorf("sink: unknown format %q, expected csv or jsonl", format)
+	}
+}
+
+func withTimestamp(path string, suffix string) string {
+	if suffix == "" {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	return fmt.Sprintf("%s-%s%s", base, suffix, ext)
+}
+
+// Multi fans every Write out to all of sinks, stopping at the first
+// error, and closes all of them on Close regardless of earlier errors.
+func Multi(sinks ...Sink) Sink {
+	return &multiSink{sinks: sinks}
+}
+
+type multiSink struct {
+	sinks []Sink
+}
+
+func (m *multiSink) Write(ctx context.Context, reg registration.CompanyRegistration) error {
+	for _, s := range m.sinks {
+		if err := s.Write(ctx, reg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *multiSink) Close() error {
+	var firstErr error
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,74 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDispatchesByScheme(t *testing.T) {
+	dir := t.TempDir()
+
+	cases := []struct {
+		spec    string
+		want    Sink
+		wantErr bool
+	}{
+		{spec: "csv:" + filepath.Join(dir, "out.csv"), want: &delimitedSink{}},
+		{spec: "jsonl:" + filepath.Join(dir, "out.jsonl"), want: &jsonlSink{}},
+		{spec: "sqlite:" + filepath.Join(dir, "out.db"), want: &sqliteSink{}},
+		{spec: "webhook:https://example.com/hook", want: &webhookSink{}},
+		{spec: "stdout:csv", want: &delimitedSink{}},
+		{spec: "stdout:jsonl", want: &jsonlSink{}},
+		{spec: "stdout:yaml", wantErr: true},
+		{spec: "carrier-pigeon:out", wantErr: true},
+		{spec: "no-scheme-separator", wantErr: true},
+	}
+
+	for _, c := range cases {
+		s, err := New(c.spec, "")
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("New(%q) = nil error, want one", c.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("New(%q) returned error: %s", c.spec, err.Error())
+		}
+		defer s.Close()
+
+		switch c.want.(type) {
+		case *delimitedSink:
+			if _, ok := s.(*delimitedSink); !ok {
+				t.Errorf("New(%q) = %T, want *delimitedSink", c.spec, s)
+			}
+		case *jsonlSink:
+			if _, ok := s.(*jsonlSink); !ok {
+				t.Errorf("New(%q) = %T, want *jsonlSink", c.spec, s)
+			}
+		case *sqliteSink:
+			if _, ok := s.(*sqliteSink); !ok {
+				t.Errorf("New(%q) = %T, want *sqliteSink", c.spec, s)
+			}
+		case *webhookSink:
+			if _, ok := s.(*webhookSink); !ok {
+				t.Errorf("New(%q) = %T, want *webhookSink", c.spec, s)
+			}
+		}
+	}
+}
+
+func TestNewWithTimestampSuffix(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := New("csv:"+filepath.Join(dir, "out.csv"), "20240101")
+	if err != nil {
+		t.Fatalf("New returned error: %s", err.Error())
+	}
+	defer s.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "out-20240101.csv")); err != nil {
+		t.Errorf("expected timestamp-suffixed file to exist: %s", err.Error())
+	}
+}
@@ -0,0 +1,72 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+type sqliteSink struct {
+	db   *sql.DB
+	stmt *sql.Stmt
+}
+
+func newSQLiteSink(path string) (*sqliteSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("sink: unable to open %s: %s", path, err.Error())
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS registrations (
+			reg_no        TEXT NOT NULL,
+			date          TEXT,
+			name          TEXT,
+			address       TEXT,
+			city          TEXT,
+			postal_code   TEXT,
+			notice_type   TEXT,
+			court         TEXT,
+			register_kind TEXT
+		);
+		CREATE UNIQUE INDEX IF NOT EXISTS registrations_reg_no_date ON registrations(reg_no, date);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sink: unable to create schema: %s", err.Error())
+	}
+
+	stmt, err := db.Prepare(`
+		INSERT INTO registrations (reg_no, date, name, address, city, postal_code, notice_type, court, register_kind)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(reg_no, date) DO NOTHING
+	`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sink: unable to prepare insert: %s", err.Error())
+	}
+
+	return &sqliteSink{db: db, stmt: stmt}, nil
+}
+
+func (s *sqliteSink) Write(ctx context.Context, reg registration.CompanyRegistration) error {
+	var date string
+	if reg.Date != nil {
+		date = reg.Date.Format(time.RFC3339)
+	}
+
+	_, err := s.stmt.ExecContext(ctx, reg.RegNo, date, reg.Name, reg.Address, reg.City, reg.PostalCode, reg.NoticeType, reg.Court, reg.RegisterKind)
+	return err
+}
+
+func (s *sqliteSink) Close() error {
+	if err := s.stmt.Close(); err != nil {
+		return err
+	}
+
+	return s.db.Close()
+}
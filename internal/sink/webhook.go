@@ -0,0 +1,66 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cameronnorman/company_registraions_cli/internal/registration"
+)
+
+const webhookMaxRetries = 3
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookSink(url string) *webhookSink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *webhookSink) Write(ctx context.Context, reg registration.CompanyRegistration) error {
+	body, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= webhookMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("sink: webhook %s returned %s", s.url, resp.Status)
+			continue
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("sink: webhook %s returned %s", s.url, resp.Status)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("sink: webhook %s failed after %d retries: %s", s.url, webhookMaxRetries, lastErr.Error())
+}
+
+func (s *webhookSink) Close() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+// Package store persists, across server restarts, which registrations
+// have already been seen (so daily polling can diff out only new
+// notices) and which webhook subscriptions are active.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store is a SQLite-backed persistence layer. It is safe for concurrent
+// use, same as a *sql.DB.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if necessary) the SQLite database at path and
+// ensures its schema exists.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("store: unable to open %s: %s", path, err.Error())
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS seen_registrations (
+			reg_no TEXT NOT NULL,
+			court  TEXT NOT NULL,
+			PRIMARY KEY (reg_no, court)
+		);
+		CREATE TABLE IF NOT EXISTS subscriptions (
+			id      INTEGER PRIMARY KEY AUTOINCREMENT,
+			webhook TEXT NOT NULL,
+			cron    TEXT NOT NULL,
+			land    TEXT NOT NULL,
+			rubrik  TEXT NOT NULL
+		);
+	`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: unable to create schema: %s", err.Error())
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Seen reports whether regNo+court has already been recorded, without
+// marking it seen. Callers that must only mark a registration seen once
+// it has been successfully delivered should check Seen before doing the
+// work and call MarkSeen only after it succeeds.
+func (s *Store) Seen(regNo, court string) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM seen_registrations WHERE reg_no = ? AND court = ?)`, regNo, court).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// MarkSeen records regNo+court as seen and reports whether it was new.
+func (s *Store) MarkSeen(regNo, court string) (isNew bool, err error) {
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO seen_registrations (reg_no, court) VALUES (?, ?)`, regNo, court)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Subscription is a persisted /watch registration.
+type Subscription struct {
+	ID      int64
+	Webhook string
+	Cron    string
+	Lands   []string
+	Rubrik  string
+}
+
+// AddSubscription persists sub and returns its assigned ID.
+func (s *Store) AddSubscription(sub Subscription) (int64, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO subscriptions (webhook, cron, land, rubrik) VALUES (?, ?, ?, ?)`,
+		sub.Webhook, sub.Cron, strings.Join(sub.Lands, ","), sub.Rubrik,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// Subscriptions returns every persisted subscription, e.g. to resume
+// polling after a restart.
+func (s *Store) Subscriptions() ([]Subscription, error) {
+	rows, err := s.db.Query(`SELECT id, webhook, cron, land, rubrik FROM subscriptions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []Subscription{}
+	for rows.Next() {
+		var sub Subscription
+		var land string
+		if err := rows.Scan(&sub.ID, &sub.Webhook, &sub.Cron, &land, &sub.Rubrik); err != nil {
+			return nil, err
+		}
+		if land != "" {
+			sub.Lands = strings.Split(land, ",")
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
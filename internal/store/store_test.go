@@ -0,0 +1,116 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open returned error: %s", err.Error())
+	}
+	t.Cleanup(func() { st.Close() })
+
+	return st
+}
+
+func TestMarkSeenReportsOnlyTheFirstCallAsNew(t *testing.T) {
+	st := newTestStore(t)
+
+	isNew, err := st.MarkSeen("HRB 123456", "Stuttgart")
+	if err != nil {
+		t.Fatalf("MarkSeen returned error: %s", err.Error())
+	}
+	if !isNew {
+		t.Error("first MarkSeen of a registration reported isNew = false")
+	}
+
+	isNew, err = st.MarkSeen("HRB 123456", "Stuttgart")
+	if err != nil {
+		t.Fatalf("MarkSeen returned error: %s", err.Error())
+	}
+	if isNew {
+		t.Error("second MarkSeen of the same registration reported isNew = true")
+	}
+}
+
+func TestSeenDoesNotMark(t *testing.T) {
+	st := newTestStore(t)
+
+	seen, err := st.Seen("HRB 123456", "Stuttgart")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if seen {
+		t.Fatal("Seen reported true for a registration never marked seen")
+	}
+
+	seen, err = st.Seen("HRB 123456", "Stuttgart")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if seen {
+		t.Error("Seen reported true after being called, it must not mark as a side effect")
+	}
+
+	if _, err := st.MarkSeen("HRB 123456", "Stuttgart"); err != nil {
+		t.Fatalf("MarkSeen returned error: %s", err.Error())
+	}
+
+	seen, err = st.Seen("HRB 123456", "Stuttgart")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if !seen {
+		t.Error("Seen reported false for a registration that was marked seen")
+	}
+}
+
+func TestSeenIsScopedByCourt(t *testing.T) {
+	st := newTestStore(t)
+
+	if _, err := st.MarkSeen("HRB 123456", "Stuttgart"); err != nil {
+		t.Fatalf("MarkSeen returned error: %s", err.Error())
+	}
+
+	seen, err := st.Seen("HRB 123456", "München")
+	if err != nil {
+		t.Fatalf("Seen returned error: %s", err.Error())
+	}
+	if seen {
+		t.Error("the same RegNo at a different court was reported seen")
+	}
+}
+
+func TestSubscriptionsRoundTrip(t *testing.T) {
+	st := newTestStore(t)
+
+	sub := Subscription{Webhook: "https://example.com/hook", Cron: "@daily", Lands: []string{"bw", "by"}, Rubrik: "HRB"}
+
+	id, err := st.AddSubscription(sub)
+	if err != nil {
+		t.Fatalf("AddSubscription returned error: %s", err.Error())
+	}
+
+	subs, err := st.Subscriptions()
+	if err != nil {
+		t.Fatalf("Subscriptions returned error: %s", err.Error())
+	}
+	if len(subs) != 1 {
+		t.Fatalf("expected 1 subscription, got %d", len(subs))
+	}
+
+	got := subs[0]
+	if got.ID != id {
+		t.Errorf("ID = %d, want %d", got.ID, id)
+	}
+	if got.Webhook != sub.Webhook || got.Cron != sub.Cron || got.Rubrik != sub.Rubrik {
+		t.Errorf("Subscription = %+v, want %+v", got, sub)
+	}
+	if len(got.Lands) != 2 || got.Lands[0] != "bw" || got.Lands[1] != "by" {
+		t.Errorf("Lands = %v, want [bw by]", got.Lands)
+	}
+}